@@ -0,0 +1,24 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+// BackendAnnotationKey is the Revision annotation that selects which
+// autoscaler ScalerBackend a KPA should use (e.g. "scale" or "keda"). It
+// lives here rather than in the reconciler's autoscaling/backends package
+// so that lower-level packages, like the Service resource builder, can
+// read it without pulling in that whole subsystem.
+const BackendAnnotationKey = "autoscaling.knative.dev/backend"
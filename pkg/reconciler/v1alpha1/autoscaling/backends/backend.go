@@ -0,0 +1,104 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backends provides pluggable implementations of scaling a KPA's
+// target reference. The default implementation drives the Kubernetes
+// `/scale` subresource; other implementations may delegate scaling
+// decisions to an external autoscaler (e.g. KEDA) while Knative continues
+// to own scale-to-zero semantics.
+package backends
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/knative/serving/pkg/apis/serving"
+)
+
+// BackendAnnotationKey is the Revision annotation that selects which
+// ScalerBackend a KPA should use. When absent, BackendScale is assumed.
+// It's defined in pkg/apis/serving so packages that only need the
+// annotation key, not the backends it selects between, can read it without
+// depending on this package.
+const BackendAnnotationKey = serving.BackendAnnotationKey
+
+// ScaleTimeoutAnnotationKey is the Revision annotation that overrides how
+// long a single scale Get/Update may take (e.g. "2s"). When absent or
+// unparsable, the autoscaling package's fixed DefaultScaleTimeout applies.
+const ScaleTimeoutAnnotationKey = "autoscaling.knative.dev/scale-timeout"
+
+// Backend identifies a registered ScalerBackend implementation.
+type Backend string
+
+const (
+	// BackendScale drives scaling via the Kubernetes `/scale` subresource.
+	// This is the default and preserves pre-existing behavior.
+	BackendScale Backend = "scale"
+
+	// BackendKEDA patches the bounds of an operator-provisioned KEDA
+	// ScaledObject (see kedaBackend), while Knative continues to enforce
+	// idle/grace periods before instructing the backend to go to zero.
+	BackendKEDA Backend = "keda"
+)
+
+// TargetRef identifies the resource a ScalerBackend should scale.
+type TargetRef struct {
+	Namespace string
+	Resource  schema.GroupResource
+	Name      string
+
+	// Min and Max are the KPA's scale bounds (kpa.ScaleBounds()), needed
+	// by backends that reconcile an external scaling object rather than
+	// writing replicas directly.
+	Min int32
+	Max int32
+
+	// ScaleTimeout bounds how long a single Get/Update may take before
+	// the backend gives up, so a wedged apiserver call can't stall the
+	// reconciler goroutine. Zero means no additional deadline.
+	ScaleTimeout time.Duration
+
+	// DryRun, when true, evaluates the scale decision without applying
+	// it - used to validate the autoscaler in shadow/observe-only mode.
+	DryRun bool
+}
+
+// ScalerBackend scales a target reference to the desired scale and reports
+// the current scale observed for it. Implementations must be safe to call
+// repeatedly with the same desired scale (idempotent converges, not just
+// one-shot writes).
+type ScalerBackend interface {
+	// Scale reconciles targetRef towards desired and returns the scale
+	// that was observed/applied. current is the scale the caller already
+	// read for this tick (kpaScaler always Gets before deciding to call
+	// Scale), so implementations that don't need a fresher read should use
+	// it instead of issuing their own redundant Get.
+	Scale(ctx context.Context, targetRef TargetRef, current, desired int32) (observed int32, err error)
+}
+
+// Registry resolves a Backend name to its ScalerBackend implementation.
+type Registry map[Backend]ScalerBackend
+
+// Get returns the ScalerBackend registered for name, falling back to
+// BackendScale if name is empty or unknown.
+func (r Registry) Get(name Backend) ScalerBackend {
+	if b, ok := r[name]; ok {
+		return b
+	}
+	return r[BackendScale]
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingBackend struct{}
+
+func (r *recordingBackend) Scale(_ context.Context, _ TargetRef, _, desired int32) (int32, error) {
+	return desired, nil
+}
+
+func TestRegistryGetFallsBackToScale(t *testing.T) {
+	scaleBackend := &recordingBackend{}
+	kedaBackend := &recordingBackend{}
+	reg := Registry{
+		BackendScale: scaleBackend,
+		BackendKEDA:  kedaBackend,
+	}
+
+	tests := []struct {
+		name string
+		in   Backend
+		want ScalerBackend
+	}{
+		{name: "explicit scale", in: BackendScale, want: scaleBackend},
+		{name: "explicit keda", in: BackendKEDA, want: kedaBackend},
+		{name: "empty defaults to scale", in: "", want: scaleBackend},
+		{name: "unknown defaults to scale", in: "bogus", want: scaleBackend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reg.Get(tt.in); got != tt.want {
+				t.Errorf("Get(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+// kedaBackend.Scale races its Get/Update through the shared callWithDeadline,
+// so ScaleTimeout's deadline-propagation and leaked-call accounting are
+// covered once, generically, by scale_client_test.go rather than re-tested
+// per backend here.
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newFakeKEDAClient(so *unstructured.Unstructured) *fake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	gvrToListKind := map[schema.GroupVersionResource]string{scaledObjectGVR: "ScaledObjectList"}
+	return fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind, so)
+}
+
+func newScaledObject(ns, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata": map[string]interface{}{
+			"namespace": ns,
+			"name":      name,
+		},
+		"spec": map[string]interface{}{
+			"minReplicaCount": int64(0),
+			"maxReplicaCount": int64(100),
+		},
+	}}
+}
+
+func TestKEDABackendScaleSetsMinAndMax(t *testing.T) {
+	so := newScaledObject("ns", "target")
+	client := newFakeKEDAClient(so)
+	backend := NewKEDABackend(client)
+
+	targetRef := TargetRef{Namespace: "ns", Name: "target", Min: 2, Max: 10}
+	if _, err := backend.Scale(context.Background(), targetRef, 0, 5); err != nil {
+		t.Fatalf("Scale() error = %v", err)
+	}
+
+	got, err := client.Resource(scaledObjectGVR).Namespace("ns").Get("target", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	min, _, _ := unstructured.NestedInt64(got.Object, "spec", "minReplicaCount")
+	max, _, _ := unstructured.NestedInt64(got.Object, "spec", "maxReplicaCount")
+	if min != 2 {
+		t.Errorf("minReplicaCount = %d, want 2", min)
+	}
+	if max != 10 {
+		t.Errorf("maxReplicaCount = %d, want 10", max)
+	}
+}
+
+func TestKEDABackendScaleLeavesMaxUntouchedWhenUnbounded(t *testing.T) {
+	so := newScaledObject("ns", "target")
+	client := newFakeKEDAClient(so)
+	backend := NewKEDABackend(client)
+
+	// Max == 0 is Knative's "unbounded" sentinel: the ScaledObject's own
+	// maxReplicaCount (100, set by newScaledObject) must survive untouched
+	// so KEDA's own triggers can still scale past whatever Knative computed
+	// this tick.
+	targetRef := TargetRef{Namespace: "ns", Name: "target", Min: 1, Max: 0}
+	if _, err := backend.Scale(context.Background(), targetRef, 0, 5); err != nil {
+		t.Fatalf("Scale() error = %v", err)
+	}
+
+	got, err := client.Resource(scaledObjectGVR).Namespace("ns").Get("target", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	max, _, _ := unstructured.NestedInt64(got.Object, "spec", "maxReplicaCount")
+	if max != 100 {
+		t.Errorf("maxReplicaCount = %d, want unchanged 100", max)
+	}
+}
+
+func TestKEDABackendScaleDryRunSkipsUpdate(t *testing.T) {
+	so := newScaledObject("ns", "target")
+	client := newFakeKEDAClient(so)
+	backend := NewKEDABackend(client)
+
+	targetRef := TargetRef{Namespace: "ns", Name: "target", Min: 2, Max: 10, DryRun: true}
+	if _, err := backend.Scale(context.Background(), targetRef, 0, 5); err != nil {
+		t.Fatalf("Scale() error = %v", err)
+	}
+
+	got, err := client.Resource(scaledObjectGVR).Namespace("ns").Get("target", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	min, _, _ := unstructured.NestedInt64(got.Object, "spec", "minReplicaCount")
+	if min != 0 {
+		t.Errorf("minReplicaCount = %d, want unchanged 0 in dry-run mode", min)
+	}
+}
+
+func TestKEDABackendScaleErrorsWhenScaledObjectMissing(t *testing.T) {
+	client := newFakeKEDAClient(newScaledObject("ns", "other"))
+	backend := NewKEDABackend(client)
+
+	targetRef := TargetRef{Namespace: "ns", Name: "missing", Min: 0, Max: 10}
+	if _, err := backend.Scale(context.Background(), targetRef, 0, 5); err == nil {
+		t.Error("Scale() error = nil, want a not-found error")
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// scaleSourceOps counts how scale reads/writes were served, so operators
+// can see how much traffic the informer-backed fast path is diverting
+// away from the generic `/scale` subresource.
+var scaleSourceOps = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "knative",
+	Subsystem: "autoscaler",
+	Name:      "scale_source_operations_total",
+	Help:      "Count of scale read/write operations by path (fast_path or subresource) and verb (get or update).",
+}, []string{"path", "verb"})
+
+// scaleCacheOps counts cachingScalesGetter.Get calls by whether they were
+// served from cache or required a round-trip to the apiserver.
+var scaleCacheOps = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "knative",
+	Subsystem: "autoscaler",
+	Name:      "scale_cache_operations_total",
+	Help:      "Count of scale cache Get operations by result (hit or miss).",
+}, []string{"result"})
+
+// scaleCacheCoalesced counts Update calls that were collapsed into an
+// already in-flight identical Update rather than issued separately.
+var scaleCacheCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "knative",
+	Subsystem: "autoscaler",
+	Name:      "scale_cache_coalesced_updates_total",
+	Help:      "Count of scale Update calls coalesced into an in-flight identical Update.",
+})
+
+// scaleCallsLeaked counts calls abandoned by callWithDeadline because ctx
+// expired first. The underlying client-go call keeps running to completion
+// in its own goroutine regardless - this is the operator-visible signal
+// that a wedged apiserver is leaking goroutines rather than merely being
+// slow.
+var scaleCallsLeaked = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "knative",
+	Subsystem: "autoscaler",
+	Name:      "scale_calls_leaked_total",
+	Help:      "Count of scale Get/Update calls abandoned after their deadline expired, whose underlying goroutine is still running.",
+})
+
+func init() {
+	prometheus.MustRegister(scaleSourceOps, scaleCacheOps, scaleCacheCoalesced, scaleCallsLeaked)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+)
+
+// scaleSubresourceBackend is the default ScalerBackend. It scales targets
+// through a ScaleClient, which fast-paths Deployments and StatefulSets
+// via informer listers and typed patches before falling back to the
+// generic `/scale` subresource for other kinds, while honoring each
+// TargetRef's ScaleTimeout and DryRun.
+type scaleSubresourceBackend struct {
+	client ScaleClient
+}
+
+// NewScaleSubresourceBackend returns the ScalerBackend that scales targets
+// through source. Callers typically share a single ScaleSource between
+// this backend and their own reads so the fast path and its metrics stay
+// consistent.
+func NewScaleSubresourceBackend(source ScaleSource) ScalerBackend {
+	return &scaleSubresourceBackend{client: NewScaleClient(source)}
+}
+
+func (b *scaleSubresourceBackend) Scale(ctx context.Context, targetRef TargetRef, current, desired int32) (int32, error) {
+	if current == desired {
+		return current, nil
+	}
+	if err := b.client.Update(ctx, targetRef, desired); err != nil {
+		return 0, err
+	}
+	if targetRef.DryRun {
+		return current, nil
+	}
+	return desired, nil
+}
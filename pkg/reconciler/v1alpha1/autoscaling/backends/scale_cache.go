@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/scale"
+)
+
+// DefaultScaleCacheTTL bounds how long a cachingScalesGetter serves a
+// `/scale` Get from its cache before re-checking the apiserver. It's a
+// fixed constant, not sourced from the autoscaler ConfigMap: doing that
+// would mean adding a field to pkg/autoscaler.Config, which no caller in
+// this package currently does.
+const DefaultScaleCacheTTL = time.Second
+
+// cacheKey identifies a single scale target across namespace, resource
+// kind and name, matching how kpaScaler addresses ScaleTargetRefs.
+type cacheKey struct {
+	namespace string
+	resource  schema.GroupResource
+	name      string
+}
+
+func (k cacheKey) String() string {
+	return fmt.Sprintf("%s/%s/%s", k.namespace, k.resource.String(), k.name)
+}
+
+type cacheEntry struct {
+	scale      *autoscalingv1.Scale
+	observedAt time.Time
+}
+
+// cachingScalesGetter wraps a scale.ScalesGetter with a short-lived,
+// coalescing cache. It serves Get from the most recently observed Scale
+// within ttl, drops Update calls that would be no-ops, and collapses
+// concurrent identical Updates into a single apiserver call. This keeps
+// many KPAs converging on the same steady state from hammering the
+// apiserver's `/scale` subresource every reconcile tick.
+type cachingScalesGetter struct {
+	inner scale.ScalesGetter
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+
+	group singleflight.Group
+}
+
+// NewCachingScalesGetter returns a scale.ScalesGetter that caches and
+// coalesces Get/Update calls against inner for up to ttl.
+func NewCachingScalesGetter(inner scale.ScalesGetter, ttl time.Duration) scale.ScalesGetter {
+	return &cachingScalesGetter{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+func (c *cachingScalesGetter) Scales(namespace string) scale.ScaleInterface {
+	return &cachingScaleInterface{parent: c, namespace: namespace, inner: c.inner.Scales(namespace)}
+}
+
+type cachingScaleInterface struct {
+	parent    *cachingScalesGetter
+	namespace string
+	inner     scale.ScaleInterface
+}
+
+func (c *cachingScaleInterface) key(resource schema.GroupResource, name string) cacheKey {
+	return cacheKey{namespace: c.namespace, resource: resource, name: name}
+}
+
+func (c *cachingScaleInterface) Get(resource schema.GroupResource, name string) (*autoscalingv1.Scale, error) {
+	key := c.key(resource, name)
+
+	c.parent.mu.Lock()
+	entry, ok := c.parent.entries[key]
+	c.parent.mu.Unlock()
+	if ok && time.Since(entry.observedAt) < c.parent.ttl {
+		scaleCacheOps.WithLabelValues("hit").Inc()
+		return entry.scale.DeepCopy(), nil
+	}
+	scaleCacheOps.WithLabelValues("miss").Inc()
+
+	scl, err, _ := c.parent.group.Do("get:"+key.String(), func() (interface{}, error) {
+		return c.inner.Get(resource, name)
+	})
+	if err != nil {
+		c.parent.invalidate(key)
+		return nil, err
+	}
+	got := scl.(*autoscalingv1.Scale)
+	c.parent.observe(key, got)
+	return got, nil
+}
+
+func (c *cachingScaleInterface) Update(resource schema.GroupResource, scaleObj *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	key := c.key(resource, scaleObj.Name)
+
+	c.parent.mu.Lock()
+	entry, ok := c.parent.entries[key]
+	c.parent.mu.Unlock()
+	if ok && time.Since(entry.observedAt) < c.parent.ttl && entry.scale.Spec.Replicas == scaleObj.Spec.Replicas {
+		// No-op: the apiserver already reflects this replica count.
+		return entry.scale.DeepCopy(), nil
+	}
+
+	result, err, shared := c.parent.group.Do(fmt.Sprintf("update:%s:%d", key, scaleObj.Spec.Replicas), func() (interface{}, error) {
+		return c.inner.Update(resource, scaleObj)
+	})
+	if shared {
+		scaleCacheCoalesced.Inc()
+	}
+	if err != nil {
+		c.parent.invalidate(key)
+		return nil, err
+	}
+	updated := result.(*autoscalingv1.Scale)
+	c.parent.observe(key, updated)
+	return updated, nil
+}
+
+func (c *cachingScalesGetter) observe(key cacheKey, scl *autoscalingv1.Scale) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{scale: scl.DeepCopy(), observedAt: time.Now()}
+}
+
+// invalidate drops a cached entry. It's called after an apiserver error;
+// nothing watches the underlying resource, so a change made elsewhere is
+// only seen once the entry is older than ttl.
+func (c *cachingScalesGetter) invalidate(key cacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
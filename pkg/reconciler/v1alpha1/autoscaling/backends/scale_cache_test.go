@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/scale"
+)
+
+var testGR = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+type countingScalesGetter struct {
+	mu      sync.Mutex
+	gets    int32
+	updates int32
+	scales  map[string]*autoscalingv1.Scale
+}
+
+func newCountingScalesGetter(replicas int32) *countingScalesGetter {
+	return &countingScalesGetter{
+		scales: map[string]*autoscalingv1.Scale{
+			"dep": {ObjectMeta: metav1.ObjectMeta{Name: "dep"}, Spec: autoscalingv1.ScaleSpec{Replicas: replicas}},
+		},
+	}
+}
+
+func (c *countingScalesGetter) Scales(ns string) scale.ScaleInterface { return &countingScaleInterface{c} }
+
+type countingScaleInterface struct{ parent *countingScalesGetter }
+
+func (c *countingScaleInterface) Get(_ schema.GroupResource, name string) (*autoscalingv1.Scale, error) {
+	atomic.AddInt32(&c.parent.gets, 1)
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+	return c.parent.scales[name].DeepCopy(), nil
+}
+
+func (c *countingScaleInterface) Update(_ schema.GroupResource, s *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	atomic.AddInt32(&c.parent.updates, 1)
+	time.Sleep(5 * time.Millisecond) // widen the race window for the coalescing test
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+	c.parent.scales[s.Name] = s.DeepCopy()
+	return s.DeepCopy(), nil
+}
+
+func TestCachingScalesGetterServesGetFromCache(t *testing.T) {
+	inner := newCountingScalesGetter(2)
+	cached := NewCachingScalesGetter(inner, time.Minute)
+
+	if _, err := cached.Scales("ns").Get(testGR, "dep"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := cached.Scales("ns").Get(testGR, "dep"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(&inner.gets); got != 1 {
+		t.Errorf("inner Get() calls = %d, want 1 (second Get should be served from cache)", got)
+	}
+}
+
+func TestCachingScalesGetterDropsNoOpUpdate(t *testing.T) {
+	inner := newCountingScalesGetter(3)
+	cached := NewCachingScalesGetter(inner, time.Minute)
+	si := cached.Scales("ns")
+
+	if _, err := si.Get(testGR, "dep"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	scl, err := si.Update(testGR, &autoscalingv1.Scale{ObjectMeta: metav1.ObjectMeta{Name: "dep"}, Spec: autoscalingv1.ScaleSpec{Replicas: 3}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if scl.Spec.Replicas != 3 {
+		t.Errorf("Update() replicas = %d, want 3", scl.Spec.Replicas)
+	}
+	if got := atomic.LoadInt32(&inner.updates); got != 0 {
+		t.Errorf("inner Update() calls = %d, want 0 for a no-op update", got)
+	}
+}
+
+func TestCachingScalesGetterCoalescesConcurrentIdenticalUpdates(t *testing.T) {
+	inner := newCountingScalesGetter(1)
+	cached := NewCachingScalesGetter(inner, time.Minute)
+	si := cached.Scales("ns")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := si.Update(testGR, &autoscalingv1.Scale{ObjectMeta: metav1.ObjectMeta{Name: "dep"}, Spec: autoscalingv1.ScaleSpec{Replicas: 5}}); err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&inner.updates); got != 1 {
+		t.Errorf("inner Update() calls = %d, want 1 (concurrent identical updates should coalesce)", got)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+	"time"
+)
+
+// FieldManager identifies writes the autoscaler makes to scale targets.
+// The vendored client-go version here predates per-call UpdateOptions on
+// the scale subresource, so this constant is not sent with any request:
+// no ScaleClient or ScalerBackend method takes or forwards it.
+const FieldManager = "knative-autoscaler"
+
+// ScaleClient adapts a ScaleSource with the per-call options carried on a
+// TargetRef: a deadline that protects the caller from a wedged apiserver
+// call, and a dry-run mode that evaluates without applying.
+type ScaleClient interface {
+	Get(ctx context.Context, targetRef TargetRef) (int32, error)
+	Update(ctx context.Context, targetRef TargetRef, replicas int32) error
+}
+
+type scaleClient struct {
+	source ScaleSource
+}
+
+// NewScaleClient returns a ScaleClient that enforces TargetRef.ScaleTimeout
+// and TargetRef.DryRun around calls to source.
+func NewScaleClient(source ScaleSource) ScaleClient {
+	return &scaleClient{source: source}
+}
+
+func (c *scaleClient) Get(ctx context.Context, targetRef TargetRef) (int32, error) {
+	ctx, cancel := withOptionalTimeout(ctx, targetRef.ScaleTimeout)
+	defer cancel()
+	v, err := callWithDeadline(ctx, func() (interface{}, error) {
+		return c.source.Get(ctx, targetRef.Namespace, targetRef.Resource, targetRef.Name)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int32), nil
+}
+
+func (c *scaleClient) Update(ctx context.Context, targetRef TargetRef, replicas int32) error {
+	if targetRef.DryRun {
+		return nil
+	}
+	ctx, cancel := withOptionalTimeout(ctx, targetRef.ScaleTimeout)
+	defer cancel()
+	_, err := callWithDeadline(ctx, func() (interface{}, error) {
+		return nil, c.source.Update(ctx, targetRef.Namespace, targetRef.Resource, targetRef.Name, replicas)
+	})
+	return err
+}
+
+func withOptionalTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// callWithDeadline races fn against ctx and is shared by every backend that
+// wraps a client-go call predating context support (the generic
+// ScaleSource path here, and kedaBackend's dynamic-client calls). Since fn
+// itself never observes ctx cancellation, this is what actually bounds the
+// caller's wait to ctx's deadline rather than however long the underlying
+// apiserver call takes.
+//
+// If ctx wins the race, fn's goroutine is abandoned still running - the
+// vendored client-go calls it wraps aren't cancellable, so a genuinely
+// wedged apiserver call leaks that goroutine permanently. scaleCallsLeaked
+// counts these so operators can see it happening rather than have it be
+// invisible goroutine growth.
+func callWithDeadline(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
+	type result struct {
+		val interface{}
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		val, err := fn()
+		ch <- result{val, err}
+	}()
+	select {
+	case <-ctx.Done():
+		scaleCallsLeaked.Inc()
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.val, r.err
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// recordingScaleSourceForClient captures the context deadline it observed
+// so tests can assert ScaleClient actually propagates TargetRef.ScaleTimeout.
+type recordingScaleSourceForClient struct {
+	sawDeadline bool
+	updateCalls int
+}
+
+func (s *recordingScaleSourceForClient) Get(ctx context.Context, _ string, _ schema.GroupResource, _ string) (int32, error) {
+	_, s.sawDeadline = ctx.Deadline()
+	return 2, nil
+}
+
+func (s *recordingScaleSourceForClient) Update(ctx context.Context, _ string, _ schema.GroupResource, _ string, _ int32) error {
+	s.updateCalls++
+	_, s.sawDeadline = ctx.Deadline()
+	return nil
+}
+
+func TestScaleClientPropagatesTimeout(t *testing.T) {
+	source := &recordingScaleSourceForClient{}
+	client := NewScaleClient(source)
+
+	targetRef := TargetRef{Namespace: "ns", Resource: deploymentsGR, Name: "dep", ScaleTimeout: time.Minute}
+	if _, err := client.Get(context.Background(), targetRef); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !source.sawDeadline {
+		t.Error("expected ScaleTimeout to produce a context deadline visible to the ScaleSource")
+	}
+}
+
+func TestScaleClientNoTimeoutMeansNoDeadline(t *testing.T) {
+	source := &recordingScaleSourceForClient{}
+	client := NewScaleClient(source)
+
+	targetRef := TargetRef{Namespace: "ns", Resource: deploymentsGR, Name: "dep"}
+	if _, err := client.Get(context.Background(), targetRef); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if source.sawDeadline {
+		t.Error("expected no context deadline when ScaleTimeout is unset")
+	}
+}
+
+func TestScaleClientDryRunSkipsUpdate(t *testing.T) {
+	source := &recordingScaleSourceForClient{}
+	client := NewScaleClient(source)
+
+	targetRef := TargetRef{Namespace: "ns", Resource: deploymentsGR, Name: "dep", DryRun: true}
+	if err := client.Update(context.Background(), targetRef, 5); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if source.updateCalls != 0 {
+		t.Errorf("underlying Update() calls = %d, want 0 in dry-run mode", source.updateCalls)
+	}
+}
+
+// slowScaleSource ignores ctx entirely, standing in for the real
+// ScaleSource implementations, which are synchronous calls into
+// client-go APIs that predate context support.
+type slowScaleSource struct{ delay time.Duration }
+
+func (s *slowScaleSource) Get(context.Context, string, schema.GroupResource, string) (int32, error) {
+	time.Sleep(s.delay)
+	return 1, nil
+}
+
+func (s *slowScaleSource) Update(context.Context, string, schema.GroupResource, string, int32) error {
+	return nil
+}
+
+func TestScaleClientGetTimesOut(t *testing.T) {
+	client := NewScaleClient(&slowScaleSource{delay: time.Second})
+	before := testutil.ToFloat64(scaleCallsLeaked)
+
+	targetRef := TargetRef{Namespace: "ns", Resource: deploymentsGR, Name: "dep", ScaleTimeout: 10 * time.Millisecond}
+	_, err := client.Get(context.Background(), targetRef)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Get() error = %v, want context.DeadlineExceeded", err)
+	}
+	if after := testutil.ToFloat64(scaleCallsLeaked); after != before+1 {
+		t.Errorf("scaleCallsLeaked = %v, want %v (the abandoned Get's goroutine keeps running)", after, before+1)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newFakeListerScaleSource(t *testing.T, objs ...interface{}) (ScaleSource, *fake.Clientset) {
+	t.Helper()
+	client := fake.NewSimpleClientset()
+	for _, obj := range objs {
+		var err error
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			_, err = client.AppsV1().Deployments(o.Namespace).Create(o)
+		case *appsv1.StatefulSet:
+			_, err = client.AppsV1().StatefulSets(o.Namespace).Create(o)
+		}
+		if err != nil {
+			t.Fatalf("seeding fake client: %v", err)
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(client, 0)
+	deployInformer := factory.Apps().V1().Deployments()
+	stsInformer := factory.Apps().V1().StatefulSets()
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployInformer.Informer().GetIndexer().Add(o)
+		case *appsv1.StatefulSet:
+			stsInformer.Informer().GetIndexer().Add(o)
+		}
+	}
+
+	return NewListerScaleSource(client, deployInformer.Lister(), stsInformer.Lister()), client
+}
+
+func replicas(n int32) *int32 { return &n }
+
+func TestListerScaleSourceGet(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dep", Namespace: "ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: replicas(3)},
+	}
+	source, _ := newFakeListerScaleSource(t, dep)
+
+	got, err := source.Get(context.Background(), "ns", deploymentsGR, "dep")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != 3 {
+		t.Errorf("Get() = %d, want 3", got)
+	}
+}
+
+func TestListerScaleSourceGetUnsupportedResource(t *testing.T) {
+	source, _ := newFakeListerScaleSource(t)
+
+	_, err := source.Get(context.Background(), "ns", schema.GroupResource{Group: "custom.io", Resource: "widgets"}, "w")
+	if err != errUnsupportedResource {
+		t.Errorf("Get() error = %v, want errUnsupportedResource", err)
+	}
+}
+
+func TestListerScaleSourceUpdatePatchesReplicas(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts", Namespace: "ns"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: replicas(1)},
+	}
+	source, client := newFakeListerScaleSource(t, sts)
+
+	if err := source.Update(context.Background(), "ns", statefulSetsGR, "sts", 5); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	updated, err := client.AppsV1().StatefulSets("ns").Get("sts", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := *updated.Spec.Replicas; got != 5 {
+		t.Errorf("Spec.Replicas = %d, want 5", got)
+	}
+}
+
+func TestCompositeScaleSourceFallsBackForUnsupportedResource(t *testing.T) {
+	fallback := &recordingScaleSource{getReturn: 7}
+	source := NewCompositeScaleSource(&stubUnsupportedScaleSource{}, fallback)
+
+	got, err := source.Get(context.Background(), "ns", schema.GroupResource{Group: "custom.io", Resource: "widgets"}, "w")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != 7 {
+		t.Errorf("Get() = %d, want 7 (from fallback)", got)
+	}
+	if !fallback.getCalled {
+		t.Error("expected fallback.Get to be called")
+	}
+}
+
+type stubUnsupportedScaleSource struct{}
+
+func (s *stubUnsupportedScaleSource) Get(context.Context, string, schema.GroupResource, string) (int32, error) {
+	return 0, errUnsupportedResource
+}
+
+func (s *stubUnsupportedScaleSource) Update(context.Context, string, schema.GroupResource, string, int32) error {
+	return errUnsupportedResource
+}
+
+type recordingScaleSource struct {
+	getReturn int32
+	getCalled bool
+}
+
+func (s *recordingScaleSource) Get(context.Context, string, schema.GroupResource, string) (int32, error) {
+	s.getCalled = true
+	return s.getReturn, nil
+}
+
+func (s *recordingScaleSource) Update(context.Context, string, schema.GroupResource, string, int32) error {
+	return nil
+}
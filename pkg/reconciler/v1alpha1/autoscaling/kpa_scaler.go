@@ -19,11 +19,15 @@ package autoscaling
 import (
 	"context"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	"k8s.io/client-go/scale"
 
 	"github.com/knative/pkg/apis"
@@ -33,29 +37,60 @@ import (
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	clientset "github.com/knative/serving/pkg/client/clientset/versioned"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/autoscaling/backends"
 )
 
 const ScaleUnknown = -1
 
+// DefaultScaleTimeout bounds a scale Get/Update when the Revision doesn't
+// override it via ScaleTimeoutAnnotationKey.
+const DefaultScaleTimeout = 5 * time.Second
+
 // kpaScaler scales the target of a KPA up or down including scaling to zero.
 type kpaScaler struct {
 	servingClientSet clientset.Interface
-	scaleClientSet   scale.ScalesGetter
+	scaleClient      backends.ScaleClient
+	backends         backends.Registry
 	logger           *zap.SugaredLogger
 
+	// dryRun puts the autoscaler in shadow/observe-only mode: scale
+	// decisions are computed and logged but never applied. It's set once
+	// at construction from NewKPAScaler's dryRun argument, unlike
+	// autoscalerConfig below - there's no ConfigMap field backing it, so
+	// it isn't hot-reloadable.
+	dryRun bool
+
 	// autoscalerConfig could change over time and access to it
 	// must go through autoscalerConfigMutex
 	autoscalerConfig      *autoscaler.Config
 	autoscalerConfigMutex sync.Mutex
 }
 
-// NewKPAScaler creates a kpaScaler.
-func NewKPAScaler(servingClientSet clientset.Interface, scaleClientSet scale.ScalesGetter,
-	logger *zap.SugaredLogger, configMapWatcher configmap.Watcher) KPAScaler {
+// NewKPAScaler creates a kpaScaler. deployments and statefulSets back the
+// fast path used to read/write Deployment and StatefulSet scale targets
+// without going through the generic `/scale` subresource. dryRun puts the
+// autoscaler in shadow/observe-only mode: scale decisions are computed
+// but never applied.
+func NewKPAScaler(servingClientSet clientset.Interface, scaleClientSet scale.ScalesGetter, dynamicClientSet dynamic.Interface,
+	kubeClient kubernetes.Interface, deployments appsv1listers.DeploymentLister, statefulSets appsv1listers.StatefulSetLister,
+	dryRun bool, logger *zap.SugaredLogger, configMapWatcher configmap.Watcher) KPAScaler {
+	// The subresource path is the fallback for kinds without a typed fast
+	// path (e.g. arbitrary CRD scale targets), so it's the one that most
+	// benefits from coalescing repeated Get/Update calls across ticks.
+	cachedScaleClientSet := backends.NewCachingScalesGetter(scaleClientSet, backends.DefaultScaleCacheTTL)
+	scaleSource := backends.NewCompositeScaleSource(
+		backends.NewListerScaleSource(kubeClient, deployments, statefulSets),
+		backends.NewSubresourceScaleSource(cachedScaleClientSet),
+	)
 	ks := &kpaScaler{
 		servingClientSet: servingClientSet,
-		scaleClientSet:   scaleClientSet,
-		logger:           logger,
+		scaleClient:      backends.NewScaleClient(scaleSource),
+		backends: backends.Registry{
+			backends.BackendScale: backends.NewScaleSubresourceBackend(scaleSource),
+			backends.BackendKEDA:  backends.NewKEDABackend(dynamicClientSet),
+		},
+		dryRun: dryRun,
+		logger: logger,
 	}
 
 	// Watch for config changes.
@@ -64,6 +99,20 @@ func NewKPAScaler(servingClientSet clientset.Interface, scaleClientSet scale.Sca
 	return ks
 }
 
+// scaleTimeoutFor returns the ScaleTimeout a KPA's Revision requested via
+// ScaleTimeoutAnnotationKey, or DefaultScaleTimeout if absent or unparsable.
+func scaleTimeoutFor(kpa *kpa.PodAutoscaler) time.Duration {
+	raw, ok := kpa.Annotations[backends.ScaleTimeoutAnnotationKey]
+	if !ok {
+		return DefaultScaleTimeout
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return DefaultScaleTimeout
+	}
+	return d
+}
+
 func (ks *kpaScaler) receiveAutoscalerConfig(configMap *corev1.ConfigMap) {
 	newAutoscalerConfig, err := autoscaler.NewConfigFromConfigMap(configMap)
 	ks.autoscalerConfigMutex.Lock()
@@ -120,14 +169,25 @@ func (ks *kpaScaler) Scale(ctx context.Context, kpa *kpa.PodAutoscaler, desiredS
 	}
 	resource := apis.KindToResource(gv.WithKind(kpa.Spec.ScaleTargetRef.Kind)).GroupResource()
 	resourceName := kpa.Spec.ScaleTargetRef.Name
+	backend := ks.backends.Get(backends.Backend(kpa.Annotations[backends.BackendAnnotationKey]))
+
+	min, max := kpa.ScaleBounds()
+	targetRef := backends.TargetRef{
+		Namespace:    kpa.Namespace,
+		Resource:     resource,
+		Name:         resourceName,
+		Min:          min,
+		Max:          max,
+		ScaleTimeout: scaleTimeoutFor(kpa),
+		DryRun:       ks.dryRun,
+	}
 
 	// Identify the current scale.
-	scl, err := ks.scaleClientSet.Scales(kpa.Namespace).Get(resource, resourceName)
+	currentScale, err := ks.scaleClient.Get(ctx, targetRef)
 	if err != nil {
 		logger.Errorf("Resource %q not found.", resourceName, zap.Error(err))
 		return desiredScale, err
 	}
-	currentScale := scl.Spec.Replicas
 
 	if desiredScale == 0 {
 		// We should only scale to zero when both of the following conditions are true:
@@ -177,10 +237,11 @@ func (ks *kpaScaler) Scale(ctx context.Context, kpa *kpa.PodAutoscaler, desiredS
 	}
 	logger.Infof("Scaling from %d to %d", currentScale, desiredScale)
 
-	// Scale the target reference.
-	scl.Spec.Replicas = desiredScale
-	_, err = ks.scaleClientSet.Scales(kpa.Namespace).Update(resource, scl)
-	if err != nil {
+	// Scale the target reference through the selected backend, passing
+	// along the currentScale already read above so backends that don't
+	// need a fresher value (e.g. scaleSubresourceBackend) skip a redundant
+	// Get.
+	if _, err := backend.Scale(ctx, targetRef, currentScale, desiredScale); err != nil {
 		logger.Errorf("Error scaling target reference %v.", resourceName, zap.Error(err))
 		return desiredScale, err
 	}
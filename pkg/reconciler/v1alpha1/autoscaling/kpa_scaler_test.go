@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kpa "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/autoscaling/backends"
+)
+
+// spyBackend records the current/desired scale it was called with and
+// reports back whatever was requested.
+type spyBackend struct {
+	called  bool
+	current int32
+	desired int32
+}
+
+func (s *spyBackend) Scale(_ context.Context, _ backends.TargetRef, current, desired int32) (int32, error) {
+	s.called, s.current, s.desired = true, current, desired
+	return desired, nil
+}
+
+// stubScaleClient always reports the same current scale, regardless of
+// targetRef, so tests don't need a real ScaleSource.
+type stubScaleClient struct{ current int32 }
+
+func (c *stubScaleClient) Get(context.Context, backends.TargetRef) (int32, error) {
+	return c.current, nil
+}
+
+func (c *stubScaleClient) Update(context.Context, backends.TargetRef, int32) error { return nil }
+
+func testKPA(annotations map[string]string) *kpa.PodAutoscaler {
+	revGVK := v1alpha1.SchemeGroupVersion.WithKind("Revision")
+	return &kpa.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "rev",
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(&metav1.ObjectMeta{Name: "rev"}, revGVK),
+			},
+		},
+		Spec: kpa.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "dep",
+			},
+		},
+	}
+}
+
+func TestKPAScalerSelectsBackendFromAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+	}{
+		{name: "no annotation defaults to scale backend"},
+		{name: "explicit scale annotation", annotations: map[string]string{backends.BackendAnnotationKey: string(backends.BackendScale)}},
+		{name: "keda annotation selects keda backend", annotations: map[string]string{backends.BackendAnnotationKey: string(backends.BackendKEDA)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaleBackend := &spyBackend{}
+			kedaBackend := &spyBackend{}
+			ks := &kpaScaler{
+				scaleClient: &stubScaleClient{current: 1},
+				backends: backends.Registry{
+					backends.BackendScale: scaleBackend,
+					backends.BackendKEDA:  kedaBackend,
+				},
+				logger: zap.NewNop().Sugar(),
+			}
+
+			if _, err := ks.Scale(context.Background(), testKPA(tt.annotations), 2); err != nil {
+				t.Fatalf("Scale() error = %v", err)
+			}
+
+			wantKEDA := tt.annotations[backends.BackendAnnotationKey] == string(backends.BackendKEDA)
+			if kedaBackend.called != wantKEDA {
+				t.Errorf("keda backend called = %v, want %v", kedaBackend.called, wantKEDA)
+			}
+			if scaleBackend.called != !wantKEDA {
+				t.Errorf("scale backend called = %v, want %v", scaleBackend.called, !wantKEDA)
+			}
+		})
+	}
+}
+
+func TestKPAScalerPassesCurrentScaleToBackend(t *testing.T) {
+	scaleBackend := &spyBackend{}
+	ks := &kpaScaler{
+		scaleClient: &stubScaleClient{current: 1},
+		backends:    backends.Registry{backends.BackendScale: scaleBackend},
+		logger:      zap.NewNop().Sugar(),
+	}
+
+	if _, err := ks.Scale(context.Background(), testKPA(nil), 3); err != nil {
+		t.Fatalf("Scale() error = %v", err)
+	}
+	if scaleBackend.current != 1 {
+		t.Errorf("backend saw current = %d, want 1 (the value kpaScaler already read)", scaleBackend.current)
+	}
+	if scaleBackend.desired != 3 {
+		t.Errorf("backend saw desired = %d, want 3", scaleBackend.desired)
+	}
+}
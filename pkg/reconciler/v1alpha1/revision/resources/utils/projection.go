@@ -0,0 +1,187 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ProjectionAnnotationKey is the Revision annotation carrying a
+// ProjectionSpec that projects individual fields of Spec.CustomData onto
+// the generated Service, in addition to the full-JSON default under
+// serving.CustomDataAnnotationKey.
+const ProjectionAnnotationKey = "serving.knative.dev/custom-data-projection"
+
+// ProjectionTarget is where a projected field is written on the Service.
+type ProjectionTarget string
+
+const (
+	ProjectionTargetAnnotation ProjectionTarget = "annotation"
+	ProjectionTargetLabel      ProjectionTarget = "label"
+)
+
+// ProjectionFormat controls how a projected field's value is rendered as
+// the string a Kubernetes annotation or label requires.
+type ProjectionFormat string
+
+const (
+	// ProjectionFormatString renders the value with fmt.Sprint. This is
+	// the default when Format is empty.
+	ProjectionFormatString ProjectionFormat = "string"
+	// ProjectionFormatJSON renders the value as its JSON encoding.
+	ProjectionFormatJSON ProjectionFormat = "json"
+	// ProjectionFormatBase64 renders the value's string form, base64-encoded.
+	ProjectionFormatBase64 ProjectionFormat = "base64"
+)
+
+// FieldProjection maps one field of Spec.CustomData onto a single
+// annotation or label of the generated Service.
+type FieldProjection struct {
+	// JSONPath addresses a field within CustomData, e.g. ".foo.bar".
+	// Only plain object traversal is supported, no array indices or
+	// wildcards.
+	JSONPath string `json:"jsonPath"`
+	// Target is where the field is written: "annotation" or "label".
+	Target ProjectionTarget `json:"target"`
+	// Key is the annotation or label key to write.
+	Key string `json:"key"`
+	// Format controls how the value is rendered. Defaults to "string".
+	Format ProjectionFormat `json:"format"`
+}
+
+// ProjectionSpec is the parsed form of the ProjectionAnnotationKey annotation.
+type ProjectionSpec struct {
+	Fields []FieldProjection `json:"fields"`
+}
+
+// parseProjectionSpec reads and unmarshals the ProjectionSpec from rev's
+// annotations. It returns a nil spec, nil error when the annotation is
+// absent, since projection is opt-in.
+func parseProjectionSpec(rev *v1alpha1.Revision) (*ProjectionSpec, error) {
+	raw, ok := rev.Annotations[ProjectionAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	spec := &ProjectionSpec{}
+	if err := json.Unmarshal([]byte(raw), spec); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ProjectionAnnotationKey, err)
+	}
+	return spec, nil
+}
+
+// applyProjection walks customData per spec's field mappings and writes
+// each resolved value onto desiredService's Annotations or Labels.
+func applyProjection(spec *ProjectionSpec, customData map[string]interface{}, desiredService *corev1.Service) error {
+	for _, field := range spec.Fields {
+		value, ok := lookupJSONPath(customData, field.JSONPath)
+		if !ok {
+			continue
+		}
+
+		rendered, err := renderProjectionValue(value, field.Format)
+		if err != nil {
+			return fmt.Errorf("rendering %s: %w", field.JSONPath, err)
+		}
+
+		switch field.Target {
+		case ProjectionTargetLabel:
+			if errs := validation.IsQualifiedName(field.Key); len(errs) > 0 {
+				return fmt.Errorf("invalid label key %q: %s", field.Key, strings.Join(errs, "; "))
+			}
+			if errs := validation.IsValidLabelValue(rendered); len(errs) > 0 {
+				return fmt.Errorf("invalid label value for key %q: %s", field.Key, strings.Join(errs, "; "))
+			}
+			if desiredService.Labels == nil {
+				desiredService.Labels = make(map[string]string)
+			}
+			desiredService.Labels[field.Key] = rendered
+		case ProjectionTargetAnnotation, "":
+			if errs := validation.IsQualifiedName(field.Key); len(errs) > 0 {
+				return fmt.Errorf("invalid annotation key %q: %s", field.Key, strings.Join(errs, "; "))
+			}
+			if desiredService.Annotations == nil {
+				desiredService.Annotations = make(map[string]string)
+			}
+			desiredService.Annotations[field.Key] = rendered
+		default:
+			return fmt.Errorf("unknown projection target %q for key %q", field.Target, field.Key)
+		}
+	}
+	return nil
+}
+
+// lookupJSONPath resolves a dotted path like ".foo.bar" against data,
+// returning ok=false if any segment is missing or not itself an object.
+func lookupJSONPath(data map[string]interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return data, true
+	}
+
+	var cur interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func renderProjectionValue(value interface{}, format ProjectionFormat) (string, error) {
+	switch format {
+	case ProjectionFormatJSON:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case ProjectionFormatBase64:
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value))), nil
+	case ProjectionFormatString, "":
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("unknown projection format %q", format)
+	}
+}
+
+// ExtractCustomDataFromServiceAnnotations is the reverse of
+// AddCustomDataFromRevisionSpecToServiceAnnotations: it decodes the
+// full-JSON custom-data blob a Service carries back into a generic map.
+func ExtractCustomDataFromServiceAnnotations(annotations map[string]string) (map[string]interface{}, error) {
+	raw, ok := annotations[serving.CustomDataAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, fmt.Errorf("parsing custom data: %w", err)
+	}
+	return data, nil
+}
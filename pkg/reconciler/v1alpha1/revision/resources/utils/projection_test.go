@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	data := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": "baz",
+			"num": float64(42),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   interface{}
+		wantOk bool
+	}{
+		{name: "nested string", path: ".foo.bar", want: "baz", wantOk: true},
+		{name: "nested number", path: ".foo.num", want: float64(42), wantOk: true},
+		{name: "missing field", path: ".foo.missing", want: nil, wantOk: false},
+		{name: "missing top level", path: ".missing", want: nil, wantOk: false},
+		{name: "traverses through non-object", path: ".foo.bar.baz", want: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookupJSONPath(data, tt.path)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderProjectionValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		format  ProjectionFormat
+		want    string
+		wantErr bool
+	}{
+		{name: "default string", value: "hello", format: "", want: "hello"},
+		{name: "explicit string coerces number", value: float64(7), format: ProjectionFormatString, want: "7"},
+		{name: "json", value: map[string]interface{}{"a": float64(1)}, format: ProjectionFormatJSON, want: `{"a":1}`},
+		{name: "base64", value: "hello", format: ProjectionFormatBase64, want: "aGVsbG8="},
+		{name: "unknown format", value: "x", format: "yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderProjectionValue(tt.value, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProjectionWritesAnnotationsAndLabels(t *testing.T) {
+	spec := &ProjectionSpec{Fields: []FieldProjection{
+		{JSONPath: ".team", Target: ProjectionTargetLabel, Key: "acme.io/team"},
+		{JSONPath: ".config", Target: ProjectionTargetAnnotation, Key: "acme.io/config", Format: ProjectionFormatJSON},
+	}}
+	customData := map[string]interface{}{
+		"team":   "payments",
+		"config": map[string]interface{}{"retries": float64(3)},
+	}
+	service := &corev1.Service{}
+
+	if err := applyProjection(spec, customData, service); err != nil {
+		t.Fatalf("applyProjection() error = %v", err)
+	}
+
+	if got := service.Labels["acme.io/team"]; got != "payments" {
+		t.Errorf("label acme.io/team = %q, want %q", got, "payments")
+	}
+	if got := service.Annotations["acme.io/config"]; got != `{"retries":3}` {
+		t.Errorf("annotation acme.io/config = %q, want %q", got, `{"retries":3}`)
+	}
+}
+
+func TestApplyProjectionRejectsInvalidLabelValue(t *testing.T) {
+	spec := &ProjectionSpec{Fields: []FieldProjection{
+		{JSONPath: ".note", Target: ProjectionTargetLabel, Key: "acme.io/note"},
+	}}
+	customData := map[string]interface{}{"note": "not a valid label value!"}
+	service := &corev1.Service{}
+
+	if err := applyProjection(spec, customData, service); err == nil {
+		t.Error("expected an error for an invalid label value, got nil")
+	}
+}
+
+func TestApplyProjectionRejectsInvalidKey(t *testing.T) {
+	spec := &ProjectionSpec{Fields: []FieldProjection{
+		{JSONPath: ".note", Target: ProjectionTargetAnnotation, Key: "not a key!"},
+	}}
+	customData := map[string]interface{}{"note": "fine"}
+	service := &corev1.Service{}
+
+	if err := applyProjection(spec, customData, service); err == nil {
+		t.Error("expected an error for an invalid annotation key, got nil")
+	}
+}
+
+func TestApplyProjectionSkipsMissingField(t *testing.T) {
+	spec := &ProjectionSpec{Fields: []FieldProjection{
+		{JSONPath: ".missing", Target: ProjectionTargetAnnotation, Key: "acme.io/missing"},
+	}}
+	service := &corev1.Service{}
+
+	if err := applyProjection(spec, map[string]interface{}{}, service); err != nil {
+		t.Fatalf("applyProjection() error = %v", err)
+	}
+	if _, ok := service.Annotations["acme.io/missing"]; ok {
+		t.Error("expected no annotation to be written for a missing field")
+	}
+}
+
+func TestExtractCustomDataFromServiceAnnotationsRoundTrips(t *testing.T) {
+	annotations := map[string]string{
+		serving.CustomDataAnnotationKey: `{"team":"payments"}`,
+	}
+
+	data, err := ExtractCustomDataFromServiceAnnotations(annotations)
+	if err != nil {
+		t.Fatalf("ExtractCustomDataFromServiceAnnotations() error = %v", err)
+	}
+	if got := data["team"]; got != "payments" {
+		t.Errorf("data[team] = %v, want %q", got, "payments")
+	}
+}
+
+func TestExtractCustomDataFromServiceAnnotationsAbsent(t *testing.T) {
+	data, err := ExtractCustomDataFromServiceAnnotations(map[string]string{})
+	if err != nil {
+		t.Fatalf("ExtractCustomDataFromServiceAnnotations() error = %v", err)
+	}
+	if data != nil {
+		t.Errorf("data = %v, want nil", data)
+	}
+}
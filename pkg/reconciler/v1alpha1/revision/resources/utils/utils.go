@@ -2,6 +2,8 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -15,6 +17,28 @@ func AddCustomDataFromRevisionSpecToServiceAnnotations(desiredService *corev1.Se
 	if err != nil {
 		return err
 	}
+	// The full-JSON blob remains the backwards-compatible default; the
+	// projection annotation below additionally surfaces individual
+	// fields for controllers that don't want to parse it.
 	desiredService.Annotations[serving.CustomDataAnnotationKey] = string(customDataJSON)
-	return err
+
+	// Surface the selected autoscaler backend on the generated Service so
+	// downstream controllers (ingress, mesh, RBAC bootstrapping) can see
+	// which scaling path a Revision is using without inspecting the KPA.
+	if backend, ok := rev.Annotations[serving.BackendAnnotationKey]; ok {
+		desiredService.Annotations[serving.BackendAnnotationKey] = backend
+	}
+
+	spec, err := parseProjectionSpec(rev)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		return nil
+	}
+	var customData map[string]interface{}
+	if err := json.Unmarshal(customDataJSON, &customData); err != nil {
+		return fmt.Errorf("re-decoding custom data for projection: %w", err)
+	}
+	return applyProjection(spec, customData, desiredService)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddCustomDataFromRevisionSpecToServiceAnnotationsFullJSONOnly(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		Spec: v1alpha1.RevisionSpec{
+			CustomData: map[string]interface{}{"team": "payments"},
+		},
+	}
+	service := &corev1.Service{}
+
+	if err := AddCustomDataFromRevisionSpecToServiceAnnotations(service, rev); err != nil {
+		t.Fatalf("AddCustomDataFromRevisionSpecToServiceAnnotations() error = %v", err)
+	}
+	if got := service.Annotations[serving.CustomDataAnnotationKey]; got != `{"team":"payments"}` {
+		t.Errorf("%s = %q, want %q", serving.CustomDataAnnotationKey, got, `{"team":"payments"}`)
+	}
+	if _, ok := service.Annotations[serving.BackendAnnotationKey]; ok {
+		t.Error("expected no backend annotation when the Revision doesn't set one")
+	}
+}
+
+func TestAddCustomDataFromRevisionSpecToServiceAnnotationsPassesThroughBackend(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{serving.BackendAnnotationKey: "keda"},
+		},
+		Spec: v1alpha1.RevisionSpec{CustomData: map[string]interface{}{}},
+	}
+	service := &corev1.Service{}
+
+	if err := AddCustomDataFromRevisionSpecToServiceAnnotations(service, rev); err != nil {
+		t.Fatalf("AddCustomDataFromRevisionSpecToServiceAnnotations() error = %v", err)
+	}
+	if got := service.Annotations[serving.BackendAnnotationKey]; got != "keda" {
+		t.Errorf("%s = %q, want %q", serving.BackendAnnotationKey, got, "keda")
+	}
+}
+
+func TestAddCustomDataFromRevisionSpecToServiceAnnotationsAppliesProjection(t *testing.T) {
+	projectionSpec := `{"fields":[{"jsonPath":".team","target":"label","key":"acme.io/team"}]}`
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{ProjectionAnnotationKey: projectionSpec},
+		},
+		Spec: v1alpha1.RevisionSpec{
+			CustomData: map[string]interface{}{"team": "payments"},
+		},
+	}
+	service := &corev1.Service{}
+
+	if err := AddCustomDataFromRevisionSpecToServiceAnnotations(service, rev); err != nil {
+		t.Fatalf("AddCustomDataFromRevisionSpecToServiceAnnotations() error = %v", err)
+	}
+	if got := service.Annotations[serving.CustomDataAnnotationKey]; got != `{"team":"payments"}` {
+		t.Errorf("%s = %q, want %q", serving.CustomDataAnnotationKey, got, `{"team":"payments"}`)
+	}
+	if got := service.Labels["acme.io/team"]; got != "payments" {
+		t.Errorf("label acme.io/team = %q, want %q", got, "payments")
+	}
+}
+
+func TestAddCustomDataFromRevisionSpecToServiceAnnotationsRejectsInvalidProjectionSpec(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{ProjectionAnnotationKey: "not json"},
+		},
+		Spec: v1alpha1.RevisionSpec{CustomData: map[string]interface{}{}},
+	}
+	service := &corev1.Service{}
+
+	if err := AddCustomDataFromRevisionSpecToServiceAnnotations(service, rev); err == nil {
+		t.Error("expected an error for an unparsable projection spec, got nil")
+	}
+}